@@ -0,0 +1,62 @@
+// Copyright 2016 Martin Angers. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpparms
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+)
+
+// ErrUnsupportedMediaType is returned by Parse when the request's
+// Content-Type does not match any of the built-in media types or any
+// entry in Parser.ContentTypeOverrides.
+var ErrUnsupportedMediaType = errors.New("httpparms: unsupported media type")
+
+// Parse parses r into dst, selecting the decoding method based on the
+// request's Content-Type header. Requests with the GET, HEAD or
+// DELETE method, or with no Content-Type, are parsed with ParseQuery.
+// Otherwise the media type (its parameters, such as charset, are
+// stripped before matching) selects the method:
+//   - application/json is parsed with ParseQueryJSON
+//   - application/x-www-form-urlencoded is parsed with ParseQueryForm
+//   - multipart/form-data is parsed with ParseMultipart
+//
+// Parser.ContentTypeOverrides, if set, is consulted first and lets
+// callers register handlers for additional media types (e.g.
+// "application/vnd.api+json"). If no method matches, or the
+// Content-Type header is malformed, Parse returns
+// ErrUnsupportedMediaType so the caller can respond with 415.
+func (p *Parser) Parse(r *http.Request, dst interface{}) error {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete:
+		return p.ParseQuery(r, dst)
+	}
+
+	ct := r.Header.Get("Content-Type")
+	if ct == "" {
+		return p.ParseQuery(r, dst)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return ErrUnsupportedMediaType
+	}
+
+	if fn, ok := p.ContentTypeOverrides[mediaType]; ok {
+		return fn(r, dst)
+	}
+
+	switch mediaType {
+	case "application/json":
+		return p.ParseQueryJSON(r, dst)
+	case "application/x-www-form-urlencoded":
+		return p.ParseQueryForm(r, dst)
+	case "multipart/form-data":
+		return p.ParseMultipart(r, dst, 0)
+	default:
+		return ErrUnsupportedMediaType
+	}
+}
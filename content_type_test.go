@@ -0,0 +1,96 @@
+// Copyright 2016 Martin Angers. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpparms
+
+import (
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	dec := schema.NewDecoder()
+	dec.IgnoreUnknownKeys(true)
+	p := &Parser{Form: dec.Decode}
+
+	t.Run("GET uses ParseQuery", func(t *testing.T) {
+		r, err := http.NewRequest("GET", "/a?s=x", nil)
+		require.NoError(t, err)
+
+		var pt parmTest
+		require.NoError(t, p.Parse(r, &pt))
+		assert.Equal(t, "x", pt.S)
+	})
+
+	t.Run("JSON content-type uses ParseQueryJSON", func(t *testing.T) {
+		r, err := http.NewRequest("POST", "/a", strings.NewReader(`{"s": "x"}`))
+		require.NoError(t, err)
+		r.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+		var pt parmTest
+		require.NoError(t, p.Parse(r, &pt))
+		assert.Equal(t, "x", pt.S)
+	})
+
+	t.Run("form content-type uses ParseQueryForm", func(t *testing.T) {
+		r, err := http.NewRequest("POST", "/a", strings.NewReader("s=x"))
+		require.NoError(t, err)
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var pt parmTest
+		require.NoError(t, p.Parse(r, &pt))
+		assert.Equal(t, "x", pt.S)
+	})
+
+	t.Run("multipart content-type parses form values", func(t *testing.T) {
+		var body strings.Builder
+		mw := multipart.NewWriter(&body)
+		require.NoError(t, mw.WriteField("s", "x"))
+		require.NoError(t, mw.Close())
+
+		r, err := http.NewRequest("POST", "/a", strings.NewReader(body.String()))
+		require.NoError(t, err)
+		r.Header.Set("Content-Type", mw.FormDataContentType())
+
+		var pt parmTest
+		require.NoError(t, p.Parse(r, &pt))
+		assert.Equal(t, "x", pt.S)
+	})
+
+	t.Run("unknown content-type is an override or ErrUnsupportedMediaType", func(t *testing.T) {
+		r, err := http.NewRequest("POST", "/a", strings.NewReader("x"))
+		require.NoError(t, err)
+		r.Header.Set("Content-Type", "application/vnd.api+json")
+
+		var pt parmTest
+		assert.Equal(t, ErrUnsupportedMediaType, p.Parse(r, &pt))
+
+		p2 := &Parser{
+			Form: dec.Decode,
+			ContentTypeOverrides: map[string]func(*http.Request, interface{}) error{
+				"application/vnd.api+json": func(r *http.Request, dst interface{}) error {
+					dst.(*parmTest).S = "override"
+					return nil
+				},
+			},
+		}
+		require.NoError(t, p2.Parse(r, &pt))
+		assert.Equal(t, "override", pt.S)
+	})
+
+	t.Run("malformed content-type is ErrUnsupportedMediaType", func(t *testing.T) {
+		r, err := http.NewRequest("POST", "/a", strings.NewReader("x"))
+		require.NoError(t, err)
+		r.Header.Set("Content-Type", "application/json; =")
+
+		var pt parmTest
+		assert.Equal(t, ErrUnsupportedMediaType, p.Parse(r, &pt))
+	})
+}
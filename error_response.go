@@ -0,0 +1,59 @@
+// Copyright 2016 Martin Angers. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpparms
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ErrorResponse is the envelope written by (*Parser).WriteError. The
+// default encoding is JSON:
+//
+//	{"error": "...", "code": 400, "parameters": ["email", "age"]}
+type ErrorResponse struct {
+	Error      string   `json:"error"`
+	Code       int      `json:"code"`
+	Parameters []string `json:"parameters,omitempty"`
+}
+
+// WriteError builds an ErrorResponse from err - its message, code,
+// and the parameters extracted by p.ParametersFromErr - and writes it
+// to w using p.ErrorEncoder, or a JSON encoder if it is nil. A nil err
+// is written with an empty message and no parameters.
+//
+// If p.StatusCodeFromErr is set and returns a non-zero status for
+// err, that status is used instead of code. This lets domain errors
+// pick their own status, the same way p.ParametersExtractor lets them
+// surface their own list of parameters.
+func (p *Parser) WriteError(w http.ResponseWriter, r *http.Request, err error, code int) error {
+	if p.StatusCodeFromErr != nil {
+		if c := p.StatusCodeFromErr(err); c != 0 {
+			code = c
+		}
+	}
+
+	resp := &ErrorResponse{
+		Code:       code,
+		Parameters: p.ParametersFromErr(err),
+	}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+
+	enc := p.ErrorEncoder
+	if enc == nil {
+		enc = defaultErrorEncoder
+	}
+	return enc(w, r, resp)
+}
+
+// defaultErrorEncoder writes resp to w as a JSON object, setting the
+// response's status code and Content-Type header.
+func defaultErrorEncoder(w http.ResponseWriter, r *http.Request, resp *ErrorResponse) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(resp.Code)
+	return json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,87 @@
+// Copyright 2016 Martin Angers. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpparms
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteError(t *testing.T) {
+	p := &Parser{}
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("POST", "/a", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, p.WriteError(w, r, parmsErr{[]string{"email", "age"}}, http.StatusBadRequest))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+
+	var resp ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "email,age", resp.Error)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, []string{"age", "email"}, resp.Parameters)
+}
+
+func TestWriteErrorNilErr(t *testing.T) {
+	p := &Parser{}
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("POST", "/a", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, p.WriteError(w, r, nil, http.StatusOK))
+
+	var resp ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, ErrorResponse{Code: http.StatusOK}, resp)
+}
+
+func TestWriteErrorStatusCodeFromErr(t *testing.T) {
+	domainErr := errors.New("not found")
+	p := &Parser{
+		StatusCodeFromErr: func(err error) int {
+			if errors.Is(err, domainErr) {
+				return http.StatusNotFound
+			}
+			return 0
+		},
+	}
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("POST", "/a", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, p.WriteError(w, r, domainErr, http.StatusBadRequest))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestWriteErrorCustomEncoder(t *testing.T) {
+	p := &Parser{
+		ErrorEncoder: func(w http.ResponseWriter, r *http.Request, resp *ErrorResponse) error {
+			if r.Header.Get("Accept") == "application/xml" {
+				w.Header().Set("Content-Type", "application/xml")
+				w.WriteHeader(resp.Code)
+				_, err := w.Write([]byte("<error>" + resp.Error + "</error>"))
+				return err
+			}
+			return defaultErrorEncoder(w, r, resp)
+		},
+	}
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("POST", "/a", nil)
+	require.NoError(t, err)
+	r.Header.Set("Accept", "application/xml")
+
+	require.NoError(t, p.WriteError(w, r, errors.New("bad"), http.StatusBadRequest))
+	assert.Equal(t, "application/xml", w.Header().Get("Content-Type"))
+	assert.Equal(t, "<error>bad</error>", w.Body.String())
+}
@@ -0,0 +1,150 @@
+// Copyright 2016 Martin Angers. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpparms
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// defaultMaxMemory is the maxMemory value passed to
+// (*http.Request).ParseMultipartForm by ParseMultipart when the
+// caller does not provide one, matching the default used by
+// net/http.
+const defaultMaxMemory = 32 << 20 // 32 MB
+
+// UploadedFile wraps an uploaded multipart file part, exposing its
+// metadata and a way to read its content without requiring the
+// caller to deal with mime/multipart directly.
+type UploadedFile struct {
+	Filename    string
+	Size        int64
+	ContentType string
+
+	header *multipart.FileHeader
+}
+
+// Open opens the uploaded file for reading.
+func (f UploadedFile) Open() (multipart.File, error) {
+	return f.header.Open()
+}
+
+func newUploadedFile(h *multipart.FileHeader) UploadedFile {
+	return UploadedFile{
+		Filename:    h.Filename,
+		Size:        h.Size,
+		ContentType: h.Header.Get("Content-Type"),
+		header:      h,
+	}
+}
+
+// ParseMultipart parses the multipart/form-data body of r, decoding
+// its non-file values into dst through the FormDecoder pipeline and
+// binding its file parts into dst's fields, matched by the `schema`
+// or `form` struct tag used by the FormDecoder. A field may be typed
+// as *multipart.FileHeader, []*multipart.FileHeader or UploadedFile
+// (or *UploadedFile) to receive the corresponding file part(s).
+//
+// maxMemory is passed to (*http.Request).ParseMultipartForm; if it is
+// not greater than zero, a 32 MB default is used. If p.MaxUploadSize
+// is greater than zero, r.Body is wrapped with http.MaxBytesReader
+// using that limit before parsing, and exceeding it makes ParseMultipart
+// return ErrBodyTooLarge, the same sentinel used by ParseJSON.
+//
+// dst is then validated with p.validate, after file binding.
+func (p *Parser) ParseMultipart(r *http.Request, dst interface{}, maxMemory int64) error {
+	if p.MaxUploadSize > 0 {
+		r.Body = http.MaxBytesReader(nil, r.Body, p.MaxUploadSize)
+	}
+	if maxMemory <= 0 {
+		maxMemory = defaultMaxMemory
+	}
+
+	if err := r.ParseMultipartForm(maxMemory); err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			return ErrBodyTooLarge
+		}
+		return err
+	}
+
+	if err := p.schemaDecode(dst, r.MultipartForm.Value); err != nil {
+		return err
+	}
+
+	if err := bindMultipartFiles(dst, r.MultipartForm.File); err != nil {
+		return err
+	}
+
+	return p.validate(dst)
+}
+
+var (
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeaderSliceType = reflect.TypeOf([]*multipart.FileHeader(nil))
+	uploadedFileType    = reflect.TypeOf(UploadedFile{})
+	uploadedFilePtrType = reflect.TypeOf((*UploadedFile)(nil))
+)
+
+// bindMultipartFiles sets the fields of dst, a pointer to a struct,
+// that are tagged (via `form` or `schema`) with one of the keys of
+// files, to the corresponding uploaded file(s).
+func bindMultipartFiles(dst interface{}, files map[string][]*multipart.FileHeader) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	v = v.Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		name := multipartFieldName(t.Field(i))
+		if name == "" {
+			continue
+		}
+		hdrs := files[name]
+		if len(hdrs) == 0 {
+			continue
+		}
+
+		fv := v.Field(i)
+		if !fv.CanSet() {
+			continue
+		}
+		switch fv.Type() {
+		case fileHeaderType:
+			fv.Set(reflect.ValueOf(hdrs[0]))
+		case fileHeaderSliceType:
+			fv.Set(reflect.ValueOf(hdrs))
+		case uploadedFileType:
+			fv.Set(reflect.ValueOf(newUploadedFile(hdrs[0])))
+		case uploadedFilePtrType:
+			uf := newUploadedFile(hdrs[0])
+			fv.Set(reflect.ValueOf(&uf))
+		}
+	}
+	return nil
+}
+
+// multipartFieldName returns the form/schema tag name used to match
+// sf against a multipart file part, or "" if sf has no such tag.
+func multipartFieldName(sf reflect.StructField) string {
+	tag, ok := sf.Tag.Lookup("form")
+	if !ok {
+		tag, ok = sf.Tag.Lookup("schema")
+	}
+	if !ok {
+		return ""
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	return name
+}
@@ -0,0 +1,101 @@
+// Copyright 2016 Martin Angers. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpparms
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type uploadTest struct {
+	S      string                  `schema:"s" form:"s"`
+	File   *multipart.FileHeader   `schema:"file" form:"file"`
+	Files  []*multipart.FileHeader `schema:"files" form:"files"`
+	Avatar UploadedFile            `schema:"avatar" form:"avatar"`
+}
+
+// Validate rejects uploads whose file part is larger than 100 bytes,
+// which only works if Validate runs after the file fields are bound.
+func (u *uploadTest) Validate() error {
+	if u.File != nil && u.File.Size > 100 {
+		return errors.New("file too big")
+	}
+	return nil
+}
+
+func newMultipartRequest(t *testing.T, fields map[string]string, files map[string]string) *http.Request {
+	t.Helper()
+	var body strings.Builder
+	mw := multipart.NewWriter(&body)
+	for k, v := range fields {
+		require.NoError(t, mw.WriteField(k, v))
+	}
+	for k, content := range files {
+		fw, err := mw.CreateFormFile(k, k+".txt")
+		require.NoError(t, err)
+		_, err = fw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, mw.Close())
+
+	r, err := http.NewRequest("POST", "/a", strings.NewReader(body.String()))
+	require.NoError(t, err)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	return r
+}
+
+func TestParseMultipart(t *testing.T) {
+	dec := schema.NewDecoder()
+	dec.IgnoreUnknownKeys(true)
+	p := &Parser{Form: dec.Decode}
+
+	r := newMultipartRequest(t,
+		map[string]string{"s": "x"},
+		map[string]string{"file": "hello", "avatar": "img-bytes"})
+
+	var dst uploadTest
+	require.NoError(t, p.ParseMultipart(r, &dst, 0))
+	assert.Equal(t, "x", dst.S)
+	require.NotNil(t, dst.File)
+	assert.Equal(t, "file.txt", dst.File.Filename)
+	assert.Equal(t, "img-bytes", func() string {
+		f, err := dst.Avatar.Open()
+		require.NoError(t, err)
+		defer f.Close()
+		b := make([]byte, dst.Avatar.Size)
+		_, err = f.Read(b)
+		require.NoError(t, err)
+		return string(b)
+	}())
+}
+
+func TestParseMultipartRunsValidateAfterFileBinding(t *testing.T) {
+	dec := schema.NewDecoder()
+	dec.IgnoreUnknownKeys(true)
+	p := &Parser{Form: dec.Decode}
+
+	r := newMultipartRequest(t, nil, map[string]string{"file": strings.Repeat("a", 200)})
+
+	var dst uploadTest
+	assert.EqualError(t, p.ParseMultipart(r, &dst, 0), "file too big")
+}
+
+func TestParseMultipartMaxUploadSize(t *testing.T) {
+	dec := schema.NewDecoder()
+	dec.IgnoreUnknownKeys(true)
+	p := &Parser{Form: dec.Decode, MaxUploadSize: 10}
+
+	r := newMultipartRequest(t, nil, map[string]string{"file": strings.Repeat("a", 1000)})
+
+	var dst uploadTest
+	assert.Equal(t, ErrBodyTooLarge, p.ParseMultipart(r, &dst, 0))
+}
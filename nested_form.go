@@ -0,0 +1,238 @@
+// Copyright 2016 Martin Angers. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpparms
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// NestedFormDecoder returns a FormDecoder that expands query or form
+// keys using bracket (filter[status]=active, filter[tags][]=a) or dot
+// (user.name=x) notation into a nested map[string]interface{} /
+// []interface{} tree, marshals that tree to JSON, and unmarshals the
+// result into dst using unmarshal. If unmarshal is nil, json.Unmarshal
+// from the standard library is used.
+//
+// A bracket segment that is empty ("[]") appends to an array; a
+// bracket segment made of digits ("[0]") sets that array index, up to
+// maxNestedArrayIndex - a higher or malformed index is reported as an
+// error, rather than allocating an array that large. The tree built
+// across all of vals is also capped at maxNestedTreeElements total map
+// keys and array elements, so many distinct keys cannot add up to an
+// unbounded allocation either. A key that tries to use the same path
+// both as a scalar and as an object or array is reported as an error.
+//
+// Because the intermediate representation is JSON, destination struct
+// fields that are not strings (e.g. numbers or booleans) must use the
+// `json:",string"` tag, since form values are always decoded as
+// strings.
+//
+// The returned function keeps the FormDecoder signature, so it slots
+// into Parser.Form unchanged, e.g.:
+//
+//	p := &Parser{Form: NestedFormDecoder(nil)}
+func NestedFormDecoder(unmarshal JSONUnmarshaler) FormDecoder {
+	if unmarshal == nil {
+		unmarshal = json.Unmarshal
+	}
+	return func(dst interface{}, vals map[string][]string) error {
+		tree, err := nestedTreeFromValues(vals)
+		if err != nil {
+			return err
+		}
+		b, err := json.Marshal(tree)
+		if err != nil {
+			return err
+		}
+		return unmarshal(b, dst)
+	}
+}
+
+// maxNestedTreeElements bounds the total number of map keys and array
+// elements nestedTreeFromValues will allocate across all of vals, so
+// that many distinct keys each indexing near maxNestedArrayIndex
+// cannot add up to an unbounded amount of memory.
+const maxNestedTreeElements = 10000
+
+// nestedTreeFromValues builds a nested map[string]interface{} tree out
+// of vals, expanding bracket and dot notation keys.
+func nestedTreeFromValues(vals map[string][]string) (map[string]interface{}, error) {
+	budget := maxNestedTreeElements
+	root := map[string]interface{}{}
+	for key, vs := range vals {
+		segs, err := nestedKeySegments(key)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range vs {
+			updated, err := nestedAssign(root, segs, v, &budget)
+			if err != nil {
+				return nil, fmt.Errorf("httpparms: key %q: %w", key, err)
+			}
+			root = updated.(map[string]interface{})
+		}
+	}
+	return root, nil
+}
+
+// nestedKeySegments splits a key such as "user.name", "filter[status]"
+// or "filter[tags][]" into its path segments. An empty segment
+// denotes an array append.
+func nestedKeySegments(key string) ([]string, error) {
+	var segs []string
+	var cur []byte
+	inBracket := false
+
+	flush := func() {
+		segs = append(segs, string(cur))
+		cur = cur[:0]
+	}
+
+	for i := 0; i < len(key); i++ {
+		switch c := key[i]; {
+		case c == '.' && !inBracket:
+			if len(cur) == 0 {
+				return nil, fmt.Errorf("httpparms: invalid key %q", key)
+			}
+			flush()
+		case c == '[':
+			if inBracket {
+				return nil, fmt.Errorf("httpparms: invalid key %q", key)
+			}
+			if len(cur) > 0 {
+				flush()
+			}
+			inBracket = true
+		case c == ']':
+			if !inBracket {
+				return nil, fmt.Errorf("httpparms: invalid key %q", key)
+			}
+			flush()
+			inBracket = false
+		default:
+			cur = append(cur, c)
+		}
+	}
+	if inBracket {
+		return nil, fmt.Errorf("httpparms: invalid key %q", key)
+	}
+	if len(cur) > 0 {
+		flush()
+	}
+	if len(segs) == 0 {
+		return nil, fmt.Errorf("httpparms: invalid key %q", key)
+	}
+	return segs, nil
+}
+
+// maxNestedArrayIndex bounds the array index accepted in a bracket
+// segment (e.g. "tags[50000000]"). Without a cap, a single crafted
+// key could force nestedAssign to allocate a slice of that length.
+const maxNestedArrayIndex = 10000
+
+// nestedAssign sets value at the path described by segs inside node,
+// creating maps and slices as needed, and returns the (possibly new)
+// node. It returns an error if a segment's expected shape (scalar,
+// array or object) collides with what is already there.
+//
+// budget is the number of map keys and array elements still allowed
+// to be allocated across the whole tree; it is decremented for every
+// new one and nestedAssign errors out once it runs out, rather than
+// letting many distinct key paths add up to unbounded memory.
+func nestedAssign(node interface{}, segs []string, value string, budget *int) (interface{}, error) {
+	if len(segs) == 0 {
+		switch node.(type) {
+		case nil:
+			return value, nil
+		case map[string]interface{}, []interface{}:
+			return nil, fmt.Errorf("expected a scalar, found %T", node)
+		default:
+			return value, nil
+		}
+	}
+
+	seg := segs[0]
+	rest := segs[1:]
+
+	if isArraySegment(seg) {
+		arr, ok := node.([]interface{})
+		if !ok && node != nil {
+			return nil, fmt.Errorf("expected an array, found %T", node)
+		}
+		if seg == "" {
+			if err := consumeBudget(budget, 1); err != nil {
+				return nil, err
+			}
+			child, err := nestedAssign(nil, rest, value, budget)
+			if err != nil {
+				return nil, err
+			}
+			return append(arr, child), nil
+		}
+		idx, err := strconv.Atoi(seg)
+		if err != nil || idx < 0 || idx > maxNestedArrayIndex {
+			return nil, fmt.Errorf("array index %q out of range", seg)
+		}
+		if grow := idx + 1 - len(arr); grow > 0 {
+			if err := consumeBudget(budget, grow); err != nil {
+				return nil, err
+			}
+			for len(arr) <= idx {
+				arr = append(arr, nil)
+			}
+		}
+		child, err := nestedAssign(arr[idx], rest, value, budget)
+		if err != nil {
+			return nil, err
+		}
+		arr[idx] = child
+		return arr, nil
+	}
+
+	obj, ok := node.(map[string]interface{})
+	if !ok && node != nil {
+		return nil, fmt.Errorf("expected an object, found %T", node)
+	}
+	if obj == nil {
+		obj = map[string]interface{}{}
+	}
+	if _, exists := obj[seg]; !exists {
+		if err := consumeBudget(budget, 1); err != nil {
+			return nil, err
+		}
+	}
+	child, err := nestedAssign(obj[seg], rest, value, budget)
+	if err != nil {
+		return nil, err
+	}
+	obj[seg] = child
+	return obj, nil
+}
+
+// consumeBudget decrements *budget by n, returning an error instead if
+// that would take it below zero.
+func consumeBudget(budget *int, n int) error {
+	if n > *budget {
+		return fmt.Errorf("nested form tree exceeds %d elements", maxNestedTreeElements)
+	}
+	*budget -= n
+	return nil
+}
+
+// isArraySegment reports whether seg denotes an array position: empty
+// (append) or made entirely of digits (an index).
+func isArraySegment(seg string) bool {
+	if seg == "" {
+		return true
+	}
+	for i := 0; i < len(seg); i++ {
+		if seg[i] < '0' || seg[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+// Copyright 2016 Martin Angers. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpparms
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type nestedFilter struct {
+	Status string   `json:"status"`
+	Tags   []string `json:"tags"`
+}
+
+type nestedDst struct {
+	Filter nestedFilter `json:"filter"`
+	User   struct {
+		Name string `json:"name"`
+	} `json:"user"`
+}
+
+func TestNestedFormDecoder(t *testing.T) {
+	dec := NestedFormDecoder(nil)
+
+	t.Run("bracket and dot notation", func(t *testing.T) {
+		vals := map[string][]string{
+			"filter[status]": {"active"},
+			"filter[tags][]": {"a", "b"},
+			"user.name":      {"x"},
+		}
+		var dst nestedDst
+		require.NoError(t, dec(&dst, vals))
+		assert.Equal(t, "active", dst.Filter.Status)
+		assert.Equal(t, []string{"a", "b"}, dst.Filter.Tags)
+		assert.Equal(t, "x", dst.User.Name)
+	})
+
+	t.Run("numeric indices", func(t *testing.T) {
+		vals := map[string][]string{
+			"filter[tags][1]": {"b"},
+			"filter[tags][0]": {"a"},
+		}
+		var dst nestedDst
+		require.NoError(t, dec(&dst, vals))
+		assert.Equal(t, []string{"a", "b"}, dst.Filter.Tags)
+	})
+
+	t.Run("scalar vs object collision is an error", func(t *testing.T) {
+		vals := map[string][]string{
+			"filter":         {"x"},
+			"filter[status]": {"active"},
+		}
+		var dst nestedDst
+		assert.Error(t, dec(&dst, vals))
+	})
+
+	t.Run("invalid key syntax is an error", func(t *testing.T) {
+		vals := map[string][]string{"filter[status": {"active"}}
+		var dst nestedDst
+		assert.Error(t, dec(&dst, vals))
+	})
+
+	t.Run("array index beyond the cap is an error, not a huge allocation", func(t *testing.T) {
+		vals := map[string][]string{"filter[tags][50000000]": {"a"}}
+		var dst nestedDst
+		assert.Error(t, dec(&dst, vals))
+	})
+
+	t.Run("array index overflowing int is an error", func(t *testing.T) {
+		vals := map[string][]string{"filter[tags][99999999999999999999]": {"a"}}
+		var dst nestedDst
+		assert.Error(t, dec(&dst, vals))
+	})
+
+	t.Run("many distinct keys each near the index cap are bounded in aggregate", func(t *testing.T) {
+		vals := make(map[string][]string, 500)
+		for i := 0; i < 500; i++ {
+			vals[fmt.Sprintf("a%d[9000]", i)] = []string{"x"}
+		}
+		var dst nestedDst
+		assert.Error(t, dec(&dst, vals))
+	})
+}
@@ -6,25 +6,27 @@
 // content of an HTTP request into a Go struct. It supports loading the
 // query string parameters, the form-encoded body and the JSON-encoded
 // body. If the struct implements the `Validator` interface, it also
-// validates the values.
+// validates the values; a Parser.StructValidator, such as the one
+// returned by PlaygroundValidator, can validate it too, whether or
+// not it implements Validator.
 //
 // It supports various form decoders and JSON unmarshalers. Common
 // such packages that satisfy the FormDecoder function are
-//     - github.com/go-playground/form
-//     - github.com/gorilla/schema
+//   - github.com/go-playground/form
+//   - github.com/gorilla/schema
 //
 // Common packages that satisfy the JSONUmarshaler function are
-//     - encoding/json in the standard library
-//     - pquerna/ffjson/ffjson
-//
+//   - encoding/json in the standard library
+//   - pquerna/ffjson/ffjson
 package httpparms
 
 import (
 	"encoding/json"
 	"errors"
-	"io/ioutil"
+	"io"
 	"net/http"
 	"net/url"
+	"sort"
 )
 
 // FormDecoderAdapter is an adapter for form decoder functions that
@@ -59,6 +61,56 @@ type Parser struct {
 	// JSON from a slice of bytes. If it is nil, json.Unmarshal
 	// from the standard library is used.
 	JSON JSONUnmarshaler
+
+	// ParametersExtractor is called by ParametersFromErr as a fallback
+	// to extract the list of request parameters that caused err, when
+	// err does not implement the Parameter or Parameters interface.
+	ParametersExtractor func(error) []string
+
+	// ContentTypeOverrides maps a media type (without its parameters,
+	// e.g. "application/vnd.api+json") to a function that parses a
+	// request of that media type into dst. It is consulted by Parse
+	// before falling back to the built-in JSON, form and multipart
+	// handling.
+	ContentTypeOverrides map[string]func(*http.Request, interface{}) error
+
+	// MaxUploadSize, if greater than zero, is the maximum number of
+	// bytes ParseMultipart will read from the request body. It is
+	// applied via http.MaxBytesReader before the body is parsed.
+	MaxUploadSize int64
+
+	// MaxBodySize, if greater than zero, is the maximum number of
+	// bytes ParseJSON will read from the request body. Exceeding it
+	// makes ParseJSON return ErrBodyTooLarge.
+	MaxBodySize int64
+
+	// StrictJSON, when true, makes ParseJSON reject JSON objects that
+	// contain fields absent from dst, using the standard library
+	// decoder's DisallowUnknownFields.
+	StrictJSON bool
+
+	// UseNumber, when true, makes ParseJSON decode JSON numbers into
+	// dst as json.Number instead of float64, using the standard
+	// library decoder's UseNumber.
+	UseNumber bool
+
+	// ErrorEncoder is called by WriteError to write an ErrorResponse
+	// to the response writer. The request is passed along so the
+	// encoder can negotiate the response's content type (e.g. write
+	// XML or problem+json for clients that ask for it in Accept). If
+	// ErrorEncoder is nil, the response is encoded as JSON.
+	ErrorEncoder func(http.ResponseWriter, *http.Request, *ErrorResponse) error
+
+	// StatusCodeFromErr, if set, is called by WriteError to let an
+	// error pick its own HTTP status code, overriding the code passed
+	// to WriteError when it returns a non-zero value.
+	StatusCodeFromErr func(error) int
+
+	// StructValidator, if set, is called after form/JSON decoding to
+	// validate dst, whether or not dst implements Validator. If dst
+	// also implements Validator, StructValidator runs first and the
+	// two errors, if any, are combined with errors.Join.
+	StructValidator func(interface{}) error
 }
 
 // Validator defines the method required for a type to validate itself.
@@ -66,6 +118,60 @@ type Validator interface {
 	Validate() error
 }
 
+// Parameter is implemented by errors that can identify the single
+// request parameter that caused them.
+type Parameter interface {
+	Parameter() string
+}
+
+// Parameters is implemented by errors that can identify the list of
+// request parameters that caused them.
+type Parameters interface {
+	Parameters() []string
+}
+
+// ParametersFromErr returns the list of request parameters that caused
+// err, sorted and with duplicates removed. It looks for an error in
+// err's chain (using errors.As) that implements Parameters, then one
+// that implements Parameter, and falls back to p.ParametersExtractor
+// if neither is found. It returns nil if no parameter can be
+// identified.
+func (p *Parser) ParametersFromErr(err error) []string {
+	if err == nil {
+		return nil
+	}
+
+	var parms []string
+	var pse Parameters
+	var pe Parameter
+	switch {
+	case errors.As(err, &pse):
+		parms = pse.Parameters()
+	case errors.As(err, &pe):
+		if s := pe.Parameter(); s != "" {
+			parms = []string{s}
+		}
+	case p.ParametersExtractor != nil:
+		parms = p.ParametersExtractor(err)
+	}
+
+	set := make(map[string]struct{}, len(parms))
+	for _, s := range parms {
+		if s != "" {
+			set[s] = struct{}{}
+		}
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(set))
+	for s := range set {
+		out = append(out, s)
+	}
+	sort.Strings(out)
+	return out
+}
+
 func (p *Parser) schemaDecode(dst interface{}, vals url.Values) error {
 	if p.Form == nil {
 		return errors.New("httpparms: no form decoder")
@@ -73,11 +179,32 @@ func (p *Parser) schemaDecode(dst interface{}, vals url.Values) error {
 	return p.Form(dst, vals)
 }
 
+// validate runs p.StructValidator and, if dst implements Validator,
+// its Validate method, in that order, and combines their errors with
+// errors.Join. It returns nil if neither reports an error.
+func (p *Parser) validate(dst interface{}) error {
+	var errs []error
+	if p.StructValidator != nil {
+		if err := p.StructValidator(dst); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if val, ok := dst.(Validator); ok {
+		if err := val.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
+
 // ParseQueryForm parses the Form parameters of r into dst. The parameters
 // may be provided in the query string or in the form-encoded body.
 // The dst value must be a pointer to a struct that contains fields
 // matching the form parameters, possibly using `schema` struct tags.
-// If dst is a Validator, Validate is called and its error returned.
+// dst is then validated with p.validate.
 func (p *Parser) ParseQueryForm(r *http.Request, dst interface{}) error {
 	if err := r.ParseForm(); err != nil {
 		return err
@@ -87,34 +214,86 @@ func (p *Parser) ParseQueryForm(r *http.Request, dst interface{}) error {
 		return err
 	}
 
-	if val, ok := dst.(Validator); ok {
-		return val.Validate()
-	}
-	return nil
+	return p.validate(dst)
 }
 
-// ParseJSON parses the body of the request as JSON and unmarshals it into
-// dst. If dst is a Validator, Validate is called and its error returned.
-// The body is parsed as JSON regardless of the content-type of the request.
+// ErrBodyTooLarge is returned by ParseJSON when the request body
+// exceeds Parser.MaxBodySize.
+var ErrBodyTooLarge = errors.New("httpparms: request body too large")
+
+// errJSONExtraData is returned by ParseJSON when the body contains
+// more than a single top-level JSON value.
+var errJSONExtraData = errors.New("httpparms: unexpected data after JSON value")
+
+// ParseJSON parses the body of the request as JSON and unmarshals it
+// into dst, then validates dst with p.validate. The body is parsed as
+// JSON regardless of the content-type of the request.
+//
+// If p.JSON is nil, the body is streamed through encoding/json's
+// Decoder, honoring p.StrictJSON and p.UseNumber, and a second value
+// after the first (trailing garbage) is rejected. If p.JSON is set,
+// the body is read in full and passed to it instead, since a custom
+// JSONUnmarshaler operates on a byte slice. In both cases, if
+// p.MaxBodySize is greater than zero, reading more than that many
+// bytes from the body makes ParseJSON return ErrBodyTooLarge.
 func (p *Parser) ParseJSON(r *http.Request, dst interface{}) error {
-	b, err := ioutil.ReadAll(r.Body)
+	if p.JSON != nil {
+		return p.parseJSONBytes(r, dst)
+	}
+
+	body := io.Reader(r.Body)
+	if p.MaxBodySize > 0 {
+		body = http.MaxBytesReader(nil, r.Body, p.MaxBodySize)
+	}
+
+	dec := json.NewDecoder(body)
+	if p.StrictJSON {
+		dec.DisallowUnknownFields()
+	}
+	if p.UseNumber {
+		dec.UseNumber()
+	}
+
+	switch err := dec.Decode(dst); {
+	case errors.Is(err, io.EOF):
+		// empty body: nothing to decode
+	case err != nil:
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			return ErrBodyTooLarge
+		}
+		return err
+	case dec.More():
+		return errJSONExtraData
+	}
+
+	return p.validate(dst)
+}
+
+// parseJSONBytes implements ParseJSON for the case where p.JSON is
+// set: the body is read in full, capped at p.MaxBodySize, and passed
+// to p.JSON.
+func (p *Parser) parseJSONBytes(r *http.Request, dst interface{}) error {
+	body := io.Reader(r.Body)
+	if p.MaxBodySize > 0 {
+		body = http.MaxBytesReader(nil, r.Body, p.MaxBodySize)
+	}
+
+	b, err := io.ReadAll(body)
 	if err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			return ErrBodyTooLarge
+		}
 		return err
 	}
 	if len(b) > 0 {
-		fn := p.JSON
-		if fn == nil {
-			fn = json.Unmarshal
-		}
-		if err := fn(b, dst); err != nil {
+		if err := p.JSON(b, dst); err != nil {
 			return err
 		}
 	}
 
-	if val, ok := dst.(Validator); ok {
-		return val.Validate()
-	}
-	return nil
+	return p.validate(dst)
 }
 
 // ParseQueryJSON parses the query values and the body of the request as JSON
@@ -129,15 +308,12 @@ func (p *Parser) ParseQueryJSON(r *http.Request, dst interface{}) error {
 	return p.ParseJSON(r, dst)
 }
 
-// ParseQuery parses the query values and stores the values in dst. If
-// dst is a Validator, Validate is called and its error returned.
+// ParseQuery parses the query values and stores the values in dst.
+// dst is then validated with p.validate.
 func (p *Parser) ParseQuery(r *http.Request, dst interface{}) error {
 	vals := r.URL.Query()
 	if err := p.schemaDecode(dst, vals); err != nil {
 		return err
 	}
-	if val, ok := dst.(Validator); ok {
-		return val.Validate()
-	}
-	return nil
+	return p.validate(dst)
 }
@@ -0,0 +1,69 @@
+// Copyright 2016 Martin Angers. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpparms
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJSONMaxBodySize(t *testing.T) {
+	p := &Parser{MaxBodySize: 5}
+
+	r, err := http.NewRequest("POST", "/a", strings.NewReader(`{"s": "too long"}`))
+	require.NoError(t, err)
+
+	var pt parmTest
+	assert.Equal(t, ErrBodyTooLarge, p.ParseJSON(r, &pt))
+}
+
+func TestParseJSONMaxBodySizeWithCustomUnmarshaler(t *testing.T) {
+	p := &Parser{JSON: json.Unmarshal, MaxBodySize: 5}
+
+	r, err := http.NewRequest("POST", "/a", strings.NewReader(`{"s": "too long"}`))
+	require.NoError(t, err)
+
+	var pt parmTest
+	assert.Equal(t, ErrBodyTooLarge, p.ParseJSON(r, &pt))
+}
+
+func TestParseJSONStrict(t *testing.T) {
+	p := &Parser{StrictJSON: true}
+
+	r, err := http.NewRequest("POST", "/a", strings.NewReader(`{"s": "x", "unknown": 1}`))
+	require.NoError(t, err)
+
+	var pt parmTest
+	assert.Error(t, p.ParseJSON(r, &pt))
+}
+
+func TestParseJSONTrailingData(t *testing.T) {
+	p := &Parser{}
+
+	r, err := http.NewRequest("POST", "/a", strings.NewReader(`{"s": "x"}{"s": "y"}`))
+	require.NoError(t, err)
+
+	var pt parmTest
+	assert.Equal(t, errJSONExtraData, p.ParseJSON(r, &pt))
+}
+
+func TestParseJSONUseNumber(t *testing.T) {
+	type dst struct {
+		N interface{} `json:"n"`
+	}
+	p := &Parser{UseNumber: true}
+
+	r, err := http.NewRequest("POST", "/a", strings.NewReader(`{"n": 9007199254740993}`))
+	require.NoError(t, err)
+
+	var d dst
+	require.NoError(t, p.ParseJSON(r, &d))
+	assert.Equal(t, json.Number("9007199254740993"), d.N)
+}
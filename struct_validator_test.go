@@ -0,0 +1,75 @@
+// Copyright 2016 Martin Angers. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpparms
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type svDst struct {
+	Email string `json:"email" validate:"required,email"`
+	Age   int    `json:"age" validate:"gte=0"`
+}
+
+func (d *svDst) Validate() error {
+	if d.Age > 150 {
+		return errors.New("age too large")
+	}
+	return nil
+}
+
+func TestStructValidator(t *testing.T) {
+	calls := 0
+	p := &Parser{
+		StructValidator: func(dst interface{}) error {
+			calls++
+			return nil
+		},
+	}
+
+	r, err := http.NewRequest("POST", "/a", strings.NewReader(`{"email": "x@y.com", "age": 10}`))
+	require.NoError(t, err)
+
+	var d svDst
+	require.NoError(t, p.ParseJSON(r, &d))
+	assert.Equal(t, 1, calls)
+}
+
+func TestStructValidatorRunsBeforeValidateAndJoinsErrors(t *testing.T) {
+	p := &Parser{
+		StructValidator: func(dst interface{}) error {
+			return errors.New("struct invalid")
+		},
+	}
+
+	r, err := http.NewRequest("POST", "/a", strings.NewReader(`{"email": "x@y.com", "age": 200}`))
+	require.NoError(t, err)
+
+	var d svDst
+	err = p.ParseJSON(r, &d)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "struct invalid")
+	assert.Contains(t, err.Error(), "age too large")
+	assert.Equal(t, "struct invalid\nage too large", err.Error())
+}
+
+func TestPlaygroundValidator(t *testing.T) {
+	p := &Parser{StructValidator: PlaygroundValidator(validator.New())}
+
+	r, err := http.NewRequest("POST", "/a", strings.NewReader(`{"email": "not-an-email", "age": 10}`))
+	require.NoError(t, err)
+
+	var d svDst
+	err = p.ParseJSON(r, &d)
+	require.Error(t, err)
+	assert.Equal(t, []string{"Email"}, p.ParametersFromErr(err))
+}
@@ -0,0 +1,48 @@
+// Copyright 2016 Martin Angers. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpparms
+
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// PlaygroundValidator adapts v to the func(interface{}) error
+// signature expected by Parser.StructValidator. The returned function
+// runs v.Struct on its argument and, if validation fails with
+// validator.ValidationErrors, wraps it in an error that implements
+// Parameters, returning the offending fields' names (FieldError.Field)
+// so they flow through ParametersFromErr and WriteError unchanged.
+func PlaygroundValidator(v *validator.Validate) func(interface{}) error {
+	return func(dst interface{}) error {
+		err := v.Struct(dst)
+		if err == nil {
+			return nil
+		}
+
+		var verrs validator.ValidationErrors
+		if !errors.As(err, &verrs) {
+			return err
+		}
+
+		fields := make([]string, len(verrs))
+		for i, fe := range verrs {
+			fields[i] = fe.Field()
+		}
+		return &playgroundValidationErr{fields: fields, err: err}
+	}
+}
+
+// playgroundValidationErr adapts validator.ValidationErrors to the
+// Parameters interface.
+type playgroundValidationErr struct {
+	fields []string
+	err    error
+}
+
+func (e *playgroundValidationErr) Error() string        { return e.err.Error() }
+func (e *playgroundValidationErr) Parameters() []string { return e.fields }
+func (e *playgroundValidationErr) Unwrap() error        { return e.err }